@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"spotlight.moonlight.net/internal/data"
+	"spotlight.moonlight.net/internal/data/migrations"
+)
+
+const version = "1.0.0"
+
+type config struct {
+	port int
+	env  string
+	db   struct {
+		dsn          string
+		maxOpenConns int
+		maxIdleTime  time.Duration
+	}
+	migrate struct {
+		command string
+		version int64
+	}
+}
+
+type application struct {
+	config config
+	logger *slog.Logger
+	models data.Models
+}
+
+func main() {
+	var cfg config
+
+	flag.IntVar(&cfg.port, "port", 4000, "API server port")
+	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
+
+	flag.StringVar(&cfg.db.dsn, "db-dsn", os.Getenv("GAMES_DB_DSN"), "PostgreSQL DSN")
+	flag.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "PostgreSQL max open connections")
+	flag.DurationVar(&cfg.db.maxIdleTime, "db-max-idle-time", 15*time.Minute, "PostgreSQL max connection idle time")
+
+	flag.StringVar(&cfg.migrate.command, "migrate", "", `Run database migrations: "up", "down", "status", or "goto" (use with -migrate-version)`)
+	flag.Int64Var(&cfg.migrate.version, "migrate-version", 0, `Target schema version for "-migrate goto"`)
+
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	db, err := openDB(cfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	logger.Info("database connection pool established")
+
+	if cfg.migrate.command != "" {
+		if err := runMigrateCommand(db, cfg.migrate.command, cfg.migrate.version); err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := migrations.EnsureDB(db); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	app := &application{
+		config: cfg,
+		logger: logger,
+		models: data.NewModels(db),
+	}
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.port),
+		Handler:      app.routes(),
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	logger.Info("starting server", "addr", srv.Addr, "env", cfg.env)
+	err = srv.ListenAndServe()
+	logger.Error(err.Error())
+	os.Exit(1)
+}
+
+// runMigrateCommand services the -migrate flag: it runs once and returns,
+// it does not start the API server.
+func runMigrateCommand(db *pgxpool.Pool, command string, version int64) error {
+	switch command {
+	case "up":
+		return migrations.EnsureDB(db)
+	case "down":
+		return migrations.Down(db)
+	case "status":
+		return migrations.Status(db)
+	case "goto":
+		return migrations.GotoVersion(db, version)
+	default:
+		return fmt.Errorf("unknown -migrate command %q (want up, down, status, or goto)", command)
+	}
+}
+
+func openDB(cfg config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.db.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = int32(cfg.db.maxOpenConns)
+	poolConfig.MaxConnIdleTime = cfg.db.maxIdleTime
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,82 @@
+// Package migrations embeds the SQL schema history for the games/users
+// database and drives it through goose. goose operates on *sql.DB rather than
+// a pgx pool, so every entry point borrows the pool's connection string to
+// open a throwaway database/sql handle (through pgx's stdlib driver) for the
+// duration of the run.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+func init() {
+	goose.SetBaseFS(FS)
+	if err := goose.SetDialect("postgres"); err != nil {
+		panic(err)
+	}
+}
+
+// EnsureDB runs all pending "up" migrations against db. It is called from
+// main before data.NewModels so the schema is guaranteed to exist before any
+// model issues a query.
+func EnsureDB(db *pgxpool.Pool) error {
+	return withStdlib(db, func(sqlDB *sql.DB) error {
+		return goose.Up(sqlDB, ".")
+	})
+}
+
+// Down rolls back a single migration.
+func Down(db *pgxpool.Pool) error {
+	return withStdlib(db, func(sqlDB *sql.DB) error {
+		return goose.Down(sqlDB, ".")
+	})
+}
+
+// Status reports the applied/pending state of every migration through goose's
+// logger (by default the standard log package, which writes to stderr).
+func Status(db *pgxpool.Pool) error {
+	return withStdlib(db, func(sqlDB *sql.DB) error {
+		return goose.Status(sqlDB, ".")
+	})
+}
+
+// GotoVersion migrates up or down to the exact schema version given,
+// dispatching to goose.DownTo when target is behind the currently applied
+// version and goose.UpTo otherwise.
+func GotoVersion(db *pgxpool.Pool, version int64) error {
+	return withStdlib(db, func(sqlDB *sql.DB) error {
+		current, err := goose.GetDBVersion(sqlDB)
+		if err != nil {
+			return err
+		}
+		if version < current {
+			return goose.DownTo(sqlDB, ".", version)
+		}
+		return goose.UpTo(sqlDB, ".", version)
+	})
+}
+
+// withStdlib opens a database/sql handle that shares db's connection string,
+// runs fn against it, and closes it before returning.
+func withStdlib(db *pgxpool.Pool, fn func(*sql.DB) error) error {
+	connString := db.Config().ConnConfig.Copy().ConnString()
+	sqlDB, err := sql.Open("pgx", connString)
+	if err != nil {
+		return fmt.Errorf("migrations: opening stdlib connection: %w", err)
+	}
+	defer sqlDB.Close()
+
+	if err := fn(sqlDB); err != nil {
+		return fmt.Errorf("migrations: %w", err)
+	}
+	return nil
+}